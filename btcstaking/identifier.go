@@ -0,0 +1,40 @@
+package btcstaking
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// BuildOpReturnOutput constructs the Babylon phase-1 staking identifier
+// OP_RETURN output: tag || version || staker_pk || fp_pk || staking_time,
+// with staker_pk/fp_pk as 32-byte x-only keys and staking_time as a
+// big-endian uint16. It is this layout, not the Taproot staking output
+// alone, that lets indexers find and parse a Babylon delegation on chain.
+//
+// Only the primary Babylon finality provider is encoded, since the
+// identifier format predates BSN multi-staking; pass fpPubKeys[0] from a
+// multi-staking delegation's finality provider list.
+func BuildOpReturnOutput(tag []byte, version uint8, stakerPk, fpPk *btcec.PublicKey, stakingTime uint16) ([]byte, error) {
+	if len(tag) != 4 {
+		return nil, fmt.Errorf("tag must be 4 bytes, got %d", len(tag))
+	}
+
+	payload := make([]byte, 0, 4+1+32+32+2)
+	payload = append(payload, tag...)
+	payload = append(payload, version)
+	payload = append(payload, schnorrPubKeyBytes(stakerPk)...)
+	payload = append(payload, schnorrPubKeyBytes(fpPk)...)
+	payload = binary.BigEndian.AppendUint16(payload, stakingTime)
+
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(payload).
+		Script()
+}
+
+func schnorrPubKeyBytes(pk *btcec.PublicKey) []byte {
+	return pk.SerializeCompressed()[1:]
+}
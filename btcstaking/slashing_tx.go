@@ -0,0 +1,115 @@
+package btcstaking
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// slashingRateDecimals is the number of fractional digits the Babylon API's
+// slashing_rate field carries, matching the Cosmos SDK sdk.Dec precision
+// (e.g. "0.100000000000000000").
+const slashingRateDecimals = 18
+
+var slashingRateDenominator = new(big.Int).Exp(big.NewInt(10), big.NewInt(slashingRateDecimals), nil)
+
+// parseSlashingRateNumerator parses a Cosmos SDK sdk.Dec-formatted decimal
+// string into an exact numerator over 10^slashingRateDecimals. BuildSlashingTx
+// uses this instead of strconv.ParseFloat + float64 multiplication, which
+// cannot reproduce Babylon's on-chain integer decimal math bit for bit and
+// can diverge by a satoshi, changing the BIP-341 sighash.
+func parseSlashingRateNumerator(s string) (*big.Int, error) {
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(fracPart) > slashingRateDecimals {
+		return nil, fmt.Errorf("slashing rate %q has more than %d fractional digits", s, slashingRateDecimals)
+	}
+	fracPart += strings.Repeat("0", slashingRateDecimals-len(fracPart))
+
+	numerator, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid slashing rate %q", s)
+	}
+	return numerator, nil
+}
+
+// BuildSlashingTx constructs an unsigned slashing transaction spending a
+// single staking or unbonding output identified by fundingOutpoint.
+//
+// Output 0 pays floor(fundingAmount * slashingRate) satoshis to
+// slashingPkScript, the burn destination published in Babylon Genesis
+// parameters. Output 1 carries the remainder, minus minSlashingTxFeeSat left
+// unspent as the transaction's miner fee, back to the staker behind
+// changePkScript, obtained from BuildRelativeTimelockTaprootScript. The fee
+// must actually leave the transaction rather than be refunded into change,
+// since BIP-341 SigHashDefault commits to every output amount: any sighash
+// computed here must match Babylon's real slashing tx bit for bit. slashingRate
+// is the raw decimal string from the Babylon API's slashing_rate field,
+// parsed as an exact decimal rather than a float64.
+func BuildSlashingTx(
+	fundingOutpoint wire.OutPoint,
+	fundingAmount btcutil.Amount,
+	slashingRate string,
+	minSlashingTxFeeSat int64,
+	slashingPkScript []byte,
+	changePkScript []byte,
+) (*wire.MsgTx, error) {
+	numerator, err := parseSlashingRateNumerator(slashingRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slashing rate: %w", err)
+	}
+	if numerator.Sign() <= 0 || numerator.Cmp(slashingRateDenominator) >= 0 {
+		return nil, fmt.Errorf("slashing rate must be in (0, 1), got %s", slashingRate)
+	}
+
+	slashingAmountExact := new(big.Int).Mul(big.NewInt(int64(fundingAmount)), numerator)
+	slashingAmountExact.Div(slashingAmountExact, slashingRateDenominator)
+	if slashingAmountExact.Sign() <= 0 {
+		return nil, fmt.Errorf("slashing amount %s is not positive", slashingAmountExact)
+	}
+	slashingAmount := slashingAmountExact.Int64()
+
+	changeAmount := int64(fundingAmount) - slashingAmount - minSlashingTxFeeSat
+	if changeAmount <= 0 {
+		return nil, fmt.Errorf("change amount %d is not positive, funding amount %d too small for slashing rate %s and fee %d", changeAmount, fundingAmount, slashingRate, minSlashingTxFeeSat)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&fundingOutpoint, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(slashingAmount, slashingPkScript))
+	tx.AddTxOut(wire.NewTxOut(changeAmount, changePkScript))
+
+	return tx, nil
+}
+
+// SlashingPathSigHash computes the Taproot script-path sighash that every
+// signer of the slashing tapleaf (staker, finality provider, covenant
+// members) must sign over for slashingTx, given the output it spends and the
+// revealed leaf script of the slashing path being exercised.
+func SlashingPathSigHash(
+	slashingTx *wire.MsgTx,
+	fundingOutput *wire.TxOut,
+	leafScript []byte,
+) ([]byte, error) {
+	if len(slashingTx.TxIn) != 1 {
+		return nil, fmt.Errorf("slashing tx must have exactly one input, got %d", len(slashingTx.TxIn))
+	}
+
+	prevOutFetcher := txscript.NewCannedPrevOutputFetcher(fundingOutput.PkScript, fundingOutput.Value)
+	sigHashes := txscript.NewTxSigHashes(slashingTx, prevOutFetcher)
+
+	return txscript.CalcTapscriptSignaturehash(
+		sigHashes,
+		txscript.SigHashDefault,
+		slashingTx,
+		0,
+		prevOutFetcher,
+		txscript.NewBaseTapLeaf(leafScript),
+	)
+}
@@ -0,0 +1,65 @@
+package btcstaking
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestBuildSlashingTxPaysFee(t *testing.T) {
+	const (
+		fundingAmount       = btcutil.Amount(1_000_000)
+		slashingRate        = "0.100000000000000000"
+		minSlashingTxFeeSat = int64(1000)
+	)
+
+	tx, err := BuildSlashingTx(
+		wire.OutPoint{},
+		fundingAmount,
+		slashingRate,
+		minSlashingTxFeeSat,
+		[]byte{0x6a},
+		[]byte{0x6a},
+	)
+	if err != nil {
+		t.Fatalf("BuildSlashingTx: %v", err)
+	}
+
+	slashingAmount := tx.TxOut[0].Value
+	changeAmount := tx.TxOut[1].Value
+
+	if got, want := slashingAmount+changeAmount+minSlashingTxFeeSat, int64(fundingAmount); got != want {
+		t.Fatalf("slashingAmount(%d) + changeAmount(%d) + fee(%d) = %d, want %d (fundingAmount)",
+			slashingAmount, changeAmount, minSlashingTxFeeSat, got, want)
+	}
+}
+
+// TestBuildSlashingTxExactDecimal exercises a rate with 18 significant
+// fractional digits at a large funding amount and checks that the slashing
+// output is the exact decimal floor of fundingAmount * rate, computed with
+// big.Int: floor(1_000_000_000_000 * 0.123456789012345678) = 123456789012.
+func TestBuildSlashingTxExactDecimal(t *testing.T) {
+	const (
+		fundingAmount       = btcutil.Amount(1_000_000_000_000)
+		slashingRate        = "0.123456789012345678"
+		minSlashingTxFeeSat = int64(1000)
+	)
+
+	tx, err := BuildSlashingTx(
+		wire.OutPoint{},
+		fundingAmount,
+		slashingRate,
+		minSlashingTxFeeSat,
+		[]byte{0x6a},
+		[]byte{0x6a},
+	)
+	if err != nil {
+		t.Fatalf("BuildSlashingTx: %v", err)
+	}
+
+	const wantSlashingAmount = 123456789012
+	if got := tx.TxOut[0].Value; got != wantSlashingAmount {
+		t.Fatalf("slashing amount = %d, want %d", got, wantSlashingAmount)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"babylon_txcheck/btcstaking"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// PathInfo is the hex-encoded script and control block for a single Taproot
+// script-path spend.
+type PathInfo struct {
+	Script       string `json:"script"`
+	ControlBlock string `json:"control_block"`
+}
+
+// TimelockInfo expresses a relative timelock both in blocks and in the
+// approximate wall-clock units shown by displayParams.
+type TimelockInfo struct {
+	Blocks uint32  `json:"blocks"`
+	Days   float64 `json:"days"`
+	Weeks  float64 `json:"weeks"`
+	Months float64 `json:"months"`
+}
+
+func newTimelockInfo(blocks uint32) TimelockInfo {
+	days := float64(blocks) * 10 / 60 / 24
+	return TimelockInfo{
+		Blocks: blocks,
+		Days:   days,
+		Weeks:  days / 7,
+		Months: days / 30.44,
+	}
+}
+
+// StakingComputation is the structured result of computing the staking,
+// unbonding, and slashing-change outputs for a delegation. It is the JSON
+// contract shared by -format json and the serve subcommand's /compute
+// endpoint, so wallet UIs and indexers can reproduce it without shelling out.
+type StakingComputation struct {
+	Network           string       `json:"network"`
+	StakingAmountSat  int64        `json:"staking_amount_sat"`
+	StakingTimelock   TimelockInfo `json:"staking_timelock"`
+	UnbondingTimelock TimelockInfo `json:"unbonding_timelock"`
+
+	StakingAddress  string `json:"staking_address"`
+	StakingPkScript string `json:"staking_pk_script"`
+	StakingPaths    struct {
+		TimeLock  PathInfo `json:"timelock"`
+		Unbonding PathInfo `json:"unbonding"`
+		Slashing  PathInfo `json:"slashing"`
+	} `json:"staking_paths"`
+
+	UnbondingAddress  string `json:"unbonding_address"`
+	UnbondingPkScript string `json:"unbonding_pk_script"`
+	UnbondingPaths    struct {
+		TimeLock PathInfo `json:"timelock"`
+		Slashing PathInfo `json:"slashing"`
+	} `json:"unbonding_paths"`
+
+	SlashingChangeAddress  string `json:"slashing_change_address"`
+	SlashingChangePkScript string `json:"slashing_change_pk_script"`
+}
+
+func pathInfoFrom(spendInfo *btcstaking.SpendInfo) (PathInfo, error) {
+	controlBlockBytes, err := spendInfo.ControlBlock.ToBytes()
+	if err != nil {
+		return PathInfo{}, fmt.Errorf("failed to serialize control block: %w", err)
+	}
+	return PathInfo{
+		Script:       hex.EncodeToString(spendInfo.RevealedLeaf.Script),
+		ControlBlock: hex.EncodeToString(controlBlockBytes),
+	}, nil
+}
+
+// buildStakingComputation computes the staking, unbonding, and
+// slashing-change outputs for a delegation and returns them as a single
+// structured result, independent of how it is later rendered (text, JSON,
+// or an HTTP response).
+func buildStakingComputation(
+	stakerPubKey *btcec.PublicKey,
+	fpPubKeys []*btcec.PublicKey,
+	covenantPubKeys []*btcec.PublicKey,
+	covenantQuorum uint32,
+	stakingTime uint16,
+	unbondingTime uint32,
+	stakingAmount int64,
+	net *chaincfg.Params,
+) (*StakingComputation, error) {
+	stakingInfo, err := btcstaking.BuildStakingInfo(
+		stakerPubKey,
+		fpPubKeys,
+		covenantPubKeys,
+		covenantQuorum,
+		stakingTime,
+		btcutil.Amount(stakingAmount),
+		net,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build staking info: %w", err)
+	}
+
+	unbondingInfo, err := btcstaking.BuildUnbondingInfo(
+		stakerPubKey,
+		fpPubKeys,
+		covenantPubKeys,
+		covenantQuorum,
+		uint16(unbondingTime),
+		btcutil.Amount(stakingAmount),
+		net,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding info: %w", err)
+	}
+
+	slashingChangeOutput, err := btcstaking.BuildRelativeTimelockTaprootScript(
+		stakerPubKey,
+		uint16(unbondingTime),
+		net,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build slashing change output: %w", err)
+	}
+
+	comp := &StakingComputation{
+		Network:           net.Name,
+		StakingAmountSat:  stakingAmount,
+		StakingTimelock:   newTimelockInfo(uint32(stakingTime)),
+		UnbondingTimelock: newTimelockInfo(unbondingTime),
+	}
+
+	stakingAddress, err := btcutil.NewAddressTaproot(stakingInfo.GetPkScript()[2:], net)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staking Taproot address: %w", err)
+	}
+	comp.StakingAddress = stakingAddress.EncodeAddress()
+	comp.StakingPkScript = hex.EncodeToString(stakingInfo.GetPkScript())
+
+	stakingTimeLockSpendInfo, err := stakingInfo.TimeLockPathSpendInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staking timelock spend info: %w", err)
+	}
+	if comp.StakingPaths.TimeLock, err = pathInfoFrom(stakingTimeLockSpendInfo); err != nil {
+		return nil, err
+	}
+	stakingUnbondingSpendInfo, err := stakingInfo.UnbondingPathSpendInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staking unbonding spend info: %w", err)
+	}
+	if comp.StakingPaths.Unbonding, err = pathInfoFrom(stakingUnbondingSpendInfo); err != nil {
+		return nil, err
+	}
+	stakingSlashingSpendInfo, err := stakingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staking slashing spend info: %w", err)
+	}
+	if comp.StakingPaths.Slashing, err = pathInfoFrom(stakingSlashingSpendInfo); err != nil {
+		return nil, err
+	}
+
+	unbondingAddress, err := btcutil.NewAddressTaproot(unbondingInfo.UnbondingOutput.PkScript[2:], net)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unbonding Taproot address: %w", err)
+	}
+	comp.UnbondingAddress = unbondingAddress.EncodeAddress()
+	comp.UnbondingPkScript = hex.EncodeToString(unbondingInfo.UnbondingOutput.PkScript)
+
+	unbondingTimeLockSpendInfo, err := unbondingInfo.TimeLockPathSpendInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unbonding timelock spend info: %w", err)
+	}
+	if comp.UnbondingPaths.TimeLock, err = pathInfoFrom(unbondingTimeLockSpendInfo); err != nil {
+		return nil, err
+	}
+	unbondingSlashingSpendInfo, err := unbondingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unbonding slashing spend info: %w", err)
+	}
+	if comp.UnbondingPaths.Slashing, err = pathInfoFrom(unbondingSlashingSpendInfo); err != nil {
+		return nil, err
+	}
+
+	comp.SlashingChangeAddress = slashingChangeOutput.TapAddress.EncodeAddress()
+	comp.SlashingChangePkScript = hex.EncodeToString(slashingChangeOutput.PkScript)
+
+	return comp, nil
+}
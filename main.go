@@ -1,13 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"babylon_txcheck/btcstaking"
@@ -15,6 +20,9 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 )
 
 // BabylonVersionedParams holds a single version of staking parameters
@@ -27,31 +35,68 @@ type BabylonVersionedParams struct {
 	MinStakingTimeBlocks uint32   `json:"min_staking_time_blocks"`
 	MaxStakingTimeBlocks uint32   `json:"max_staking_time_blocks"`
 	UnbondingTimeBlocks  uint32   `json:"unbonding_time_blocks"`
+	SlashingPkScriptB64  string   `json:"slashing_pk_script"`
+	SlashingRate         string   `json:"slashing_rate"`
+	MinSlashingTxFeeSat  int64    `json:"min_slashing_tx_fee_sat"`
+	BtcActivationHeight  uint32   `json:"btc_activation_height"`
+}
+
+// DecodedSlashingPkScript base64-decodes the burn destination script
+// published by the Babylon API for this parameter version.
+func (p *BabylonVersionedParams) DecodedSlashingPkScript() ([]byte, error) {
+	pkScript, err := base64.StdEncoding.DecodeString(p.SlashingPkScriptB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode slashing_pk_script: %w", err)
+	}
+	return pkScript, nil
 }
 
 // BabylonParams holds the parameters fetched from the Babylon API
 type BabylonParams struct {
 	Data struct {
 		Params struct {
+			Btc struct {
+				Tag string `json:"tag"`
+			} `json:"btc"`
 			Bbn []BabylonVersionedParams `json:"bbn"`
 		} `json:"params"`
 	} `json:"data"`
 }
 
-// GetLatestParams returns the highest version parameters
-func (bp *BabylonParams) GetLatestParams() *BabylonVersionedParams {
+// DecodedTag hex-decodes the 4-byte Babylon protocol magic that indexers
+// look for in a staking transaction's OP_RETURN output.
+func (bp *BabylonParams) DecodedTag() ([]byte, error) {
+	tag, err := hex.DecodeString(bp.Data.Params.Btc.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tag: %w", err)
+	}
+	return tag, nil
+}
+
+// GetParamsForHeight returns the parameter version whose btc_activation_height
+// is the greatest one that is still <= btcHeight, following Babylon's ADR-23
+// versioning scheme. Pass math.MaxUint32 to select the latest activated
+// version regardless of the current BTC tip.
+func (bp *BabylonParams) GetParamsForHeight(btcHeight uint32) (*BabylonVersionedParams, error) {
 	if len(bp.Data.Params.Bbn) == 0 {
-		return nil
+		return nil, fmt.Errorf("no parameter versions found in API response")
 	}
 
-	// Find the version with the highest version number
-	latest := &bp.Data.Params.Bbn[0]
+	var selected *BabylonVersionedParams
 	for i := range bp.Data.Params.Bbn {
-		if bp.Data.Params.Bbn[i].Version > latest.Version {
-			latest = &bp.Data.Params.Bbn[i]
+		version := &bp.Data.Params.Bbn[i]
+		if version.BtcActivationHeight > btcHeight {
+			continue
+		}
+		if selected == nil || version.BtcActivationHeight > selected.BtcActivationHeight {
+			selected = version
 		}
 	}
-	return latest
+
+	if selected == nil {
+		return nil, fmt.Errorf("btc height %d precedes the first activation height in the API response", btcHeight)
+	}
+	return selected, nil
 }
 
 // parsePubKey parses a public key from hex string, supporting both:
@@ -106,12 +151,22 @@ func fetchBabylonParams(apiURL string) (*BabylonParams, error) {
 }
 
 type cliParams struct {
-	stakerPkHex    string
-	fpPkHex        string
-	stakingAmount  int64
-	stakingTime    int
-	useTestnet     bool
-	apiURL         string
+	stakerPkHex   string
+	fpPkHex       string // comma-separated list of finality provider public keys
+	stakingAmount int64
+	stakingTime   int
+	network       string
+	apiURL        string
+	btcHeight     uint64
+	format        string
+	stakerUTXOs   utxoFlag
+	changeAddress string
+}
+
+// quiet reports whether human-readable progress/section output should be
+// suppressed so stdout carries nothing but the -format json payload.
+func (p *cliParams) quiet() bool {
+	return p.format == "json"
 }
 
 func setupUsage() {
@@ -122,18 +177,33 @@ func setupUsage() {
 		fmt.Fprintf(flag.CommandLine.Output(), "  -staker-pk string\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "        Staker public key (hex: 64 chars x-only or 66 chars compressed)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -fp-pk string\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "        Finality provider public key (hex: 64 chars x-only or 66 chars compressed)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        Comma-separated finality provider public keys (hex: 64 chars x-only or 66 chars compressed).\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        Pass one Babylon FP plus one or more BSN consumer-chain FPs for multi-staking delegations.\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -amount int\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "        Staking amount in satoshis\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "\nOptional Parameters:\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -time int\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "        Staking time in blocks (default: use API minimum)\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "  -testnet\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "        Use testnet parameters (default: false, uses mainnet)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -network string\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        Bitcoin network: mainnet, testnet3, signet, or regtest (default: mainnet)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -api string\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "        Babylon API endpoint (default: https://staking-api.babylonlabs.io/v2/network-info)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -btc-height uint\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        BTC block height to select the parameter version active at (default: latest activated version)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -format string\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        Output format: text or json (default: text)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -staker-utxo txid:vout:amount:pkscript[:internal_pubkey]\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        UTXO funding the staking transaction (repeatable). pkscript is hex-encoded.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        internal_pubkey is the optional 32-byte x-only taproot internal key (hex),\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        populated in the PSBT so a wallet can sign a taproot key-path utxo.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        When set, also emits a staking PSBT and an unbonding PSBT.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -change-address string\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        Address for staking transaction change, required with -staker-utxo\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "\nExample:\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  %s -staker-pk <key> -fp-pk <key> -amount 1000000\n", "babylon_txcheck")
+		fmt.Fprintf(flag.CommandLine.Output(), "\nSubcommands:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  serve -listen :8080\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "        Expose the same computation as an HTTP API: POST /compute\n")
 	}
 }
 
@@ -145,8 +215,12 @@ func parseFlags() *cliParams {
 	flag.StringVar(&params.fpPkHex, "fp-pk", "", "")
 	flag.Int64Var(&params.stakingAmount, "amount", 0, "")
 	flag.IntVar(&params.stakingTime, "time", 0, "")
-	flag.BoolVar(&params.useTestnet, "testnet", false, "")
+	flag.StringVar(&params.network, "network", "mainnet", "")
 	flag.StringVar(&params.apiURL, "api", "https://staking-api.babylonlabs.io/v2/network-info", "")
+	flag.Uint64Var(&params.btcHeight, "btc-height", 0, "")
+	flag.StringVar(&params.format, "format", "text", "")
+	flag.Var(&params.stakerUTXOs, "staker-utxo", "")
+	flag.StringVar(&params.changeAddress, "change-address", "", "")
 	flag.Parse()
 
 	return params
@@ -162,28 +236,58 @@ func validateRequiredParams(params *cliParams) {
 	if params.stakingAmount == 0 {
 		log.Fatalf("Staking amount (-amount) is required")
 	}
+	if params.format != "text" && params.format != "json" {
+		log.Fatalf("Invalid -format %q: must be \"text\" or \"json\"", params.format)
+	}
+	if _, err := networkParams(params.network); err != nil {
+		log.Fatalf("Invalid -network: %v", err)
+	}
+	if len(params.stakerUTXOs) > 0 && params.changeAddress == "" {
+		log.Fatalf("-change-address is required when -staker-utxo is set")
+	}
+	if len(params.stakerUTXOs) > 0 && params.format == "json" {
+		log.Fatalf("-staker-utxo is not supported with -format json: the PSBTs it emits are not yet part of the JSON output contract")
+	}
 }
 
-func fetchAndValidateParams(params *cliParams) (*BabylonParams, int) {
-	fmt.Printf("Fetching parameters from Babylon API: %s\n", params.apiURL)
+func fetchAndValidateParams(params *cliParams) (*BabylonParams, *BabylonVersionedParams, int) {
+	if !params.quiet() {
+		fmt.Printf("Fetching parameters from Babylon API: %s\n", params.apiURL)
+	}
 	babylonParams, err := fetchBabylonParams(params.apiURL)
 	if err != nil {
 		log.Fatalf("Failed to fetch Babylon parameters: %v", err)
 	}
 
-	latest := babylonParams.GetLatestParams()
-	if latest == nil {
-		log.Fatalf("No parameter versions found in API response")
+	// A btc-height of 0 means "not requested" - select the latest activated
+	// version regardless of the current BTC tip.
+	lookupHeight := uint32(math.MaxUint32)
+	if params.btcHeight != 0 {
+		lookupHeight = uint32(params.btcHeight)
 	}
 
-	fmt.Printf("✓ Successfully fetched parameters (Version: %d, Covenant quorum: %d/%d)\n",
-		latest.Version, latest.CovenantQuorum, len(latest.CovenantPks))
-	fmt.Println()
+	latest, err := babylonParams.GetParamsForHeight(lookupHeight)
+	if err != nil {
+		log.Fatalf("Failed to select parameter version: %v", err)
+	}
+
+	if !params.quiet() {
+		if params.btcHeight != 0 {
+			fmt.Printf("✓ Successfully fetched parameters (Version: %d, activated at BTC height %d, Covenant quorum: %d/%d)\n",
+				latest.Version, latest.BtcActivationHeight, latest.CovenantQuorum, len(latest.CovenantPks))
+		} else {
+			fmt.Printf("✓ Successfully fetched parameters (Version: %d, Covenant quorum: %d/%d)\n",
+				latest.Version, latest.CovenantQuorum, len(latest.CovenantPks))
+		}
+		fmt.Println()
+	}
 
 	finalTime := params.stakingTime
 	if finalTime == 0 {
 		finalTime = int(latest.MinStakingTimeBlocks)
-		fmt.Printf("Using API minimum staking time: %d blocks\n", finalTime)
+		if !params.quiet() {
+			fmt.Printf("Using API minimum staking time: %d blocks\n", finalTime)
+		}
 	}
 
 	// Validate against API limits
@@ -200,7 +304,7 @@ func fetchAndValidateParams(params *cliParams) (*BabylonParams, int) {
 		log.Fatalf("Staking time %d exceeds maximum %d", finalTime, latest.MaxStakingTimeBlocks)
 	}
 
-	return babylonParams, finalTime
+	return babylonParams, latest, finalTime
 }
 
 func displayParams(amount int64, finalTime int, net *chaincfg.Params) {
@@ -211,11 +315,7 @@ func displayParams(amount int64, finalTime int, net *chaincfg.Params) {
 	fmt.Printf("Staking Amount: %d satoshis\n", amount)
 	fmt.Printf("Staking Time: %d blocks (≈ %.1f days / %.1f weeks / %.1f months)\n", finalTime, unlockDays, unlockWeeks, unlockMonths)
 
-	if net == &chaincfg.TestNet3Params {
-		fmt.Println("Network: Testnet")
-	} else {
-		fmt.Println("Network: Mainnet")
-	}
+	fmt.Printf("Network: %s\n", net.Name)
 	fmt.Println()
 }
 
@@ -235,10 +335,31 @@ func parseCovenantKeys(covenantPksHex []string) []*btcec.PublicKey {
 	return covenantPubKeys
 }
 
-func displayKeys(stakerPubKey, fpPubKey *btcec.PublicKey, covenantPubKeys []*btcec.PublicKey, quorum uint32) {
+// parseFpPubKeys parses a comma-separated list of finality provider public
+// keys, supporting Babylon's multi-staking delegations where a single
+// staking output restakes to one Babylon FP plus one or more BSN
+// consumer-chain FPs.
+func parseFpPubKeys(fpPkHexCSV string) []*btcec.PublicKey {
+	fpPksHex := strings.Split(fpPkHexCSV, ",")
+
+	fpPubKeys := make([]*btcec.PublicKey, 0, len(fpPksHex))
+	for i, fpPkHex := range fpPksHex {
+		fpPubKey, err := parsePubKey(strings.TrimSpace(fpPkHex))
+		if err != nil {
+			log.Fatalf("Failed to parse finality provider public key %d: %v", i, err)
+		}
+		fpPubKeys = append(fpPubKeys, fpPubKey)
+	}
+	return fpPubKeys
+}
+
+func displayKeys(stakerPubKey *btcec.PublicKey, fpPubKeys []*btcec.PublicKey, covenantPubKeys []*btcec.PublicKey, quorum uint32) {
 	fmt.Println("Keys Summary:")
 	fmt.Printf("  Staker PK: %s\n", hex.EncodeToString(stakerPubKey.SerializeCompressed()[1:]))
-	fmt.Printf("  Finality Provider PK: %s\n", hex.EncodeToString(fpPubKey.SerializeCompressed()[1:]))
+	fmt.Printf("  Finality Providers: %d keys\n", len(fpPubKeys))
+	for i, pk := range fpPubKeys {
+		fmt.Printf("    [%d] %s\n", i+1, hex.EncodeToString(pk.SerializeCompressed()[1:]))
+	}
 	fmt.Printf("  Covenant Committee: %d keys (quorum: %d)\n", len(covenantPubKeys), quorum)
 	for i, pk := range covenantPubKeys {
 		fmt.Printf("    [%d] %s\n", i+1, hex.EncodeToString(pk.SerializeCompressed()[1:]))
@@ -246,11 +367,11 @@ func displayKeys(stakerPubKey, fpPubKey *btcec.PublicKey, covenantPubKeys []*btc
 	fmt.Println()
 }
 
-func displayUnbondingOutput(stakerPubKey, fpPubKey *btcec.PublicKey, covenantPubKeys []*btcec.PublicKey, covenantQuorum uint32, unbondingTime uint32, stakingAmount int64, net *chaincfg.Params) {
+func displayUnbondingOutput(stakerPubKey *btcec.PublicKey, fpPubKeys []*btcec.PublicKey, covenantPubKeys []*btcec.PublicKey, covenantQuorum uint32, unbondingTime uint32, stakingAmount int64, net *chaincfg.Params) *btcstaking.UnbondingInfo {
 	// Build unbonding output
 	unbondingInfo, err := btcstaking.BuildUnbondingInfo(
 		stakerPubKey,
-		[]*btcec.PublicKey{fpPubKey},
+		fpPubKeys,
 		covenantPubKeys,
 		covenantQuorum,
 		uint16(unbondingTime),
@@ -299,6 +420,8 @@ func displayUnbondingOutput(stakerPubKey, fpPubKey *btcec.PublicKey, covenantPub
 	fmt.Println()
 	fmt.Println("  Note: This output is used as input for one of the slashing transaction, the unbonding slashing transaction")
 	fmt.Println()
+
+	return unbondingInfo
 }
 
 func displaySlashingOutputs(stakerPubKey *btcec.PublicKey, unbondingTime uint32, net *chaincfg.Params) {
@@ -334,6 +457,174 @@ func displaySlashingOutputs(stakerPubKey *btcec.PublicKey, unbondingTime uint32,
 	fmt.Println()
 }
 
+// displaySlashingTxs builds and prints the two unsigned slashing transactions
+// - one spending the staking output, one spending the unbonding output - and
+// the sighashes that the staker, finality provider(s), and each covenant
+// member must sign for the slashing tapleaf being exercised. Both
+// transactions spend an all-zero placeholder outpoint, and the printed
+// sighashes commit to it, so they differ from the sighashes of a slashing
+// transaction that spends a real staking or unbonding txid.
+func displaySlashingTxs(stakerPubKey *btcec.PublicKey, fpPubKeys []*btcec.PublicKey, covenantPubKeys []*btcec.PublicKey, stakingInfo *btcstaking.StakingInfo, unbondingInfo *btcstaking.UnbondingInfo, latest *BabylonVersionedParams, net *chaincfg.Params) {
+	slashingPkScript, err := latest.DecodedSlashingPkScript()
+	if err != nil {
+		log.Fatalf("Failed to decode slashing pk script: %v", err)
+	}
+	slashingChangeOutput, err := btcstaking.BuildRelativeTimelockTaprootScript(
+		stakerPubKey,
+		uint16(latest.UnbondingTimeBlocks),
+		net,
+	)
+	if err != nil {
+		log.Fatalf("Failed to build slashing change output: %v", err)
+	}
+
+	// Placeholder outpoint: the actual staking/unbonding txid is only known
+	// once that transaction has been broadcast. Vout 0 is Babylon's
+	// convention for the staking/unbonding output.
+	placeholderOutpoint := wire.OutPoint{Hash: chainhash.Hash{}, Index: 0}
+
+	buildAndDisplay := func(label string, fundingOutput *wire.TxOut, spendInfo *btcstaking.SpendInfo) {
+		slashingTx, err := btcstaking.BuildSlashingTx(
+			placeholderOutpoint,
+			btcutil.Amount(fundingOutput.Value),
+			latest.SlashingRate,
+			latest.MinSlashingTxFeeSat,
+			slashingPkScript,
+			slashingChangeOutput.PkScript,
+		)
+		if err != nil {
+			log.Fatalf("Failed to build %s slashing tx: %v", label, err)
+		}
+
+		var txBuf bytes.Buffer
+		if err := slashingTx.Serialize(&txBuf); err != nil {
+			log.Fatalf("Failed to serialize %s slashing tx: %v", label, err)
+		}
+
+		sigHash, err := btcstaking.SlashingPathSigHash(slashingTx, fundingOutput, spendInfo.RevealedLeaf.Script)
+		if err != nil {
+			log.Fatalf("Failed to compute %s slashing sighash: %v", label, err)
+		}
+
+		fmt.Printf("%s Slashing Transaction:\n", label)
+		fmt.Printf("  Raw Tx (hex): %s\n", hex.EncodeToString(txBuf.Bytes()))
+		fmt.Printf("  Sighash to sign (tapleaf script path): %s\n", hex.EncodeToString(sigHash))
+		fmt.Println("  Required signers over this sighash:")
+		fmt.Printf("    Staker:             %s\n", hex.EncodeToString(stakerPubKey.SerializeCompressed()[1:]))
+		for i, pk := range fpPubKeys {
+			fmt.Printf("    Finality Provider [%d]: %s\n", i+1, hex.EncodeToString(pk.SerializeCompressed()[1:]))
+		}
+		for i, pk := range covenantPubKeys {
+			fmt.Printf("    Covenant [%d]:       %s\n", i+1, hex.EncodeToString(pk.SerializeCompressed()[1:]))
+		}
+		fmt.Println("  (any covenant_quorum of the covenant members above must sign)")
+		fmt.Println()
+	}
+
+	stakingSlashSpendInfo, err := stakingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		log.Fatalf("Failed to get staking slashing spend info: %v", err)
+	}
+	buildAndDisplay("Staking", stakingInfo.StakingOutput, stakingSlashSpendInfo)
+
+	unbondingSlashSpendInfo, err := unbondingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		log.Fatalf("Failed to get unbonding slashing spend info: %v", err)
+	}
+	buildAndDisplay("Unbonding", unbondingInfo.UnbondingOutput, unbondingSlashSpendInfo)
+}
+
+// displayOpReturnIdentifier builds and prints the Babylon phase-1 staking
+// identifier (tag || version || staker_pk || fp_pk || staking_time) and
+// returns its OP_RETURN pkScript, so the emitted staking transaction carries
+// everything an indexer needs to recognize a delegation, not just the
+// Taproot staking output. Only the primary Babylon finality provider is
+// encoded; the identifier format predates BSN multi-staking.
+func displayOpReturnIdentifier(babylonParams *BabylonParams, stakerPubKey *btcec.PublicKey, fpPubKeys []*btcec.PublicKey, latest *BabylonVersionedParams, stakingTime int) []byte {
+	tag, err := babylonParams.DecodedTag()
+	if err != nil {
+		log.Fatalf("Failed to decode tag: %v", err)
+	}
+
+	if latest.Version < 0 || latest.Version > math.MaxUint8 {
+		log.Fatalf("Parameter version %d does not fit in the OP_RETURN identifier's 1-byte version field", latest.Version)
+	}
+
+	pkScript, err := btcstaking.BuildOpReturnOutput(tag, uint8(latest.Version), stakerPubKey, fpPubKeys[0], uint16(stakingTime))
+	if err != nil {
+		log.Fatalf("Failed to build staking identifier output: %v", err)
+	}
+
+	fmt.Printf("  Tag: %s\n", hex.EncodeToString(tag))
+	fmt.Printf("  Version: %d\n", latest.Version)
+	fmt.Printf("  Staker PK: %s\n", hex.EncodeToString(stakerPubKey.SerializeCompressed()[1:]))
+	fmt.Printf("  Finality Provider PK: %s\n", hex.EncodeToString(fpPubKeys[0].SerializeCompressed()[1:]))
+	if len(fpPubKeys) > 1 {
+		fmt.Printf("    (%d additional BSN finality provider(s) are not part of the phase-1 identifier)\n", len(fpPubKeys)-1)
+	}
+	fmt.Printf("  Staking Time: %d blocks\n", stakingTime)
+	fmt.Printf("  PkScript (hex): %s\n", hex.EncodeToString(pkScript))
+	fmt.Println()
+
+	return pkScript
+}
+
+// displayPSBTs builds and prints the staking transaction PSBT (funded by
+// -staker-utxo, paying the computed staking output, the OP_RETURN identifier
+// output, and change) and the unbonding transaction PSBT that spends it via
+// the unbonding tapleaf.
+func displayPSBTs(params *cliParams, stakingInfo *btcstaking.StakingInfo, unbondingInfo *btcstaking.UnbondingInfo, opReturnPkScript []byte, net *chaincfg.Params) {
+	utxos := make([]*stakerUTXO, 0, len(params.stakerUTXOs))
+	for i, raw := range params.stakerUTXOs {
+		utxo, err := parseStakerUTXO(raw)
+		if err != nil {
+			log.Fatalf("Failed to parse -staker-utxo %d: %v", i, err)
+		}
+		utxos = append(utxos, utxo)
+	}
+
+	changeAddr, err := btcutil.DecodeAddress(params.changeAddress, net)
+	if err != nil {
+		log.Fatalf("Failed to decode -change-address: %v", err)
+	}
+	changePkScript, err := txscript.PayToAddrScript(changeAddr)
+	if err != nil {
+		log.Fatalf("Failed to build change pkscript: %v", err)
+	}
+
+	stakingPacket, err := BuildStakingPSBT(utxos, stakingInfo.GetPkScript(), btcutil.Amount(stakingInfo.StakingOutput.Value), opReturnPkScript, changePkScript)
+	if err != nil {
+		log.Fatalf("Failed to build staking PSBT: %v", err)
+	}
+	stakingPsbtB64, err := stakingPacket.B64Encode()
+	if err != nil {
+		log.Fatalf("Failed to encode staking PSBT: %v", err)
+	}
+
+	fmt.Println("Staking Transaction PSBT:")
+	fmt.Printf("  %s\n", stakingPsbtB64)
+	fmt.Println()
+
+	unbondingSpendInfo, err := stakingInfo.UnbondingPathSpendInfo()
+	if err != nil {
+		log.Fatalf("Failed to get unbonding spend info: %v", err)
+	}
+
+	stakingTxid := stakingPacket.UnsignedTx.TxHash()
+	unbondingPacket, err := BuildUnbondingPSBT(stakingTxid, 0, stakingInfo.StakingOutput, unbondingInfo.UnbondingOutput, unbondingSpendInfo)
+	if err != nil {
+		log.Fatalf("Failed to build unbonding PSBT: %v", err)
+	}
+	unbondingPsbtB64, err := unbondingPacket.B64Encode()
+	if err != nil {
+		log.Fatalf("Failed to encode unbonding PSBT: %v", err)
+	}
+
+	fmt.Println("Unbonding Transaction PSBT (spends staking output via its unbonding path):")
+	fmt.Printf("  %s\n", unbondingPsbtB64)
+	fmt.Println()
+}
+
 func displayStakingOutput(stakingInfo *btcstaking.StakingInfo, net *chaincfg.Params) {
 	// Get spending path information
 	timeLockSpendInfo, err := stakingInfo.TimeLockPathSpendInfo()
@@ -388,45 +679,68 @@ func displayStakingOutput(stakingInfo *btcstaking.StakingInfo, net *chaincfg.Par
 }
 
 func main() {
-	fmt.Println("=== Babylon-Style BTC Staking Transaction Builder ===")
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
 
 	// Parse and validate CLI parameters
 	params := parseFlags()
 	validateRequiredParams(params)
 
+	if !params.quiet() {
+		fmt.Println("=== Babylon-Style BTC Staking Transaction Builder ===")
+	}
+
 	// Fetch and validate Babylon parameters
-	babylonParams, finalTime := fetchAndValidateParams(params)
+	babylonParams, latest, finalTime := fetchAndValidateParams(params)
 
 	// Select network
-	var net *chaincfg.Params
-	if params.useTestnet {
-		net = &chaincfg.TestNet3Params
-	} else {
-		net = &chaincfg.MainNetParams
+	net, err := networkParams(params.network)
+	if err != nil {
+		log.Fatalf("Invalid -network: %v", err)
 	}
 
-	// Display parameters
-	displayParams(params.stakingAmount, finalTime, net)
-
 	// Parse public keys
 	stakerPubKey, err := parsePubKey(params.stakerPkHex)
 	if err != nil {
 		log.Fatalf("Failed to parse staker public key: %v", err)
 	}
-	fpPubKey, err := parsePubKey(params.fpPkHex)
-	if err != nil {
-		log.Fatalf("Failed to parse finality provider public key: %v", err)
-	}
-	latest := babylonParams.GetLatestParams()
+	fpPubKeys := parseFpPubKeys(params.fpPkHex)
 	covenantPubKeys := parseCovenantKeys(latest.CovenantPks)
 
+	if params.format == "json" {
+		comp, err := buildStakingComputation(
+			stakerPubKey,
+			fpPubKeys,
+			covenantPubKeys,
+			latest.CovenantQuorum,
+			uint16(finalTime),
+			latest.UnbondingTimeBlocks,
+			params.stakingAmount,
+			net,
+		)
+		if err != nil {
+			log.Fatalf("Failed to compute staking outputs: %v", err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(comp); err != nil {
+			log.Fatalf("Failed to encode JSON output: %v", err)
+		}
+		return
+	}
+
+	// Display parameters
+	displayParams(params.stakingAmount, finalTime, net)
+
 	// Display keys
-	displayKeys(stakerPubKey, fpPubKey, covenantPubKeys, latest.CovenantQuorum)
+	displayKeys(stakerPubKey, fpPubKeys, covenantPubKeys, latest.CovenantQuorum)
 
 	// Build staking info using Babylon's implementation
 	stakingInfo, err := btcstaking.BuildStakingInfo(
 		stakerPubKey,
-		[]*btcec.PublicKey{fpPubKey},
+		fpPubKeys,
 		covenantPubKeys,
 		latest.CovenantQuorum,
 		uint16(finalTime),
@@ -454,7 +768,7 @@ func main() {
 	fmt.Println()
 
 	// Display unbonding output
-	displayUnbondingOutput(stakerPubKey, fpPubKey, covenantPubKeys, latest.CovenantQuorum, latest.UnbondingTimeBlocks, params.stakingAmount, net)
+	unbondingInfo := displayUnbondingOutput(stakerPubKey, fpPubKeys, covenantPubKeys, latest.CovenantQuorum, latest.UnbondingTimeBlocks, params.stakingAmount, net)
 
 	fmt.Println("════════════════════════════════════════════════════════════════════════════════")
 	fmt.Println("SLASHING OUTPUTS")
@@ -463,6 +777,23 @@ func main() {
 
 	// Display slashing outputs
 	displaySlashingOutputs(stakerPubKey, latest.UnbondingTimeBlocks, net)
+	displaySlashingTxs(stakerPubKey, fpPubKeys, covenantPubKeys, stakingInfo, unbondingInfo, latest, net)
+
+	fmt.Println("════════════════════════════════════════════════════════════════════════════════")
+	fmt.Println("STAKING TRANSACTION IDENTIFIER")
+	fmt.Println("════════════════════════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	opReturnPkScript := displayOpReturnIdentifier(babylonParams, stakerPubKey, fpPubKeys, latest, finalTime)
+
+	if len(params.stakerUTXOs) > 0 {
+		fmt.Println("════════════════════════════════════════════════════════════════════════════════")
+		fmt.Println("PSBTS")
+		fmt.Println("════════════════════════════════════════════════════════════════════════════════")
+		fmt.Println()
+
+		displayPSBTs(params, stakingInfo, unbondingInfo, opReturnPkScript, net)
+	}
 
 	fmt.Println("=== Success! ===")
 	fmt.Println("All staking, unbonding, and slashing outputs calculated successfully.")
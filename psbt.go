@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"babylon_txcheck/btcstaking"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// utxoFlag collects repeated -staker-utxo flags into a slice, since the
+// standard flag package has no built-in support for repeatable flags.
+type utxoFlag []string
+
+func (f *utxoFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *utxoFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// stakerUTXO is a single UTXO the staker is funding the staking
+// transaction with. InternalKey is nil unless the UTXO is a taproot
+// key-path output and its internal key was supplied on the command line,
+// in which case it lets BuildStakingPSBT populate BIP-371 fields so a
+// wallet knows to produce a Schnorr signature for it.
+type stakerUTXO struct {
+	Txid        chainhash.Hash
+	Vout        uint32
+	Amount      btcutil.Amount
+	PkScript    []byte
+	InternalKey []byte
+}
+
+// parseStakerUTXO parses a "-staker-utxo txid:vout:amount:pkscript[:internal_pubkey]"
+// value, where pkscript and the optional internal_pubkey are hex-encoded.
+// internal_pubkey is the 32-byte x-only taproot internal key for the UTXO;
+// omit it for non-taproot UTXOs, or when the internal key isn't known to
+// the caller.
+func parseStakerUTXO(s string) (*stakerUTXO, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 && len(parts) != 5 {
+		return nil, fmt.Errorf("expected txid:vout:amount:pkscript[:internal_pubkey], got %q", s)
+	}
+
+	txid, err := chainhash.NewHashFromStr(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid txid: %w", err)
+	}
+	vout, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vout: %w", err)
+	}
+	amount, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	pkScript, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid pkscript: %w", err)
+	}
+
+	var internalKey []byte
+	if len(parts) == 5 {
+		internalKey, err = hex.DecodeString(parts[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid internal_pubkey: %w", err)
+		}
+		if len(internalKey) != 32 {
+			return nil, fmt.Errorf("internal_pubkey must be a 32-byte x-only key, got %d bytes", len(internalKey))
+		}
+	}
+
+	return &stakerUTXO{
+		Txid:        *txid,
+		Vout:        uint32(vout),
+		Amount:      btcutil.Amount(amount),
+		PkScript:    pkScript,
+		InternalKey: internalKey,
+	}, nil
+}
+
+// BuildStakingPSBT constructs a BIP-174 PSBT for the staking transaction,
+// funded by utxos and paying the computed staking output, the Babylon
+// OP_RETURN identifier output, and change, in that order. Each input's
+// WitnessUtxo and SighashType are populated so a hardware/software wallet can
+// sign it; TaprootInternalKey is also populated for any utxo whose internal
+// key was supplied, so a wallet knows to produce a Schnorr key-path
+// signature for it. Staker utxos aren't assumed to be taproot in general -
+// for ones without an internal key, the PSBT carries no BIP-371 fields and
+// the wallet is responsible for recognizing how to sign them. The tool does
+// no fee estimation: all utxo value above stakingAmount goes to the change
+// output, so the transaction as built pays no network fee.
+func BuildStakingPSBT(utxos []*stakerUTXO, stakingPkScript []byte, stakingAmount btcutil.Amount, opReturnPkScript []byte, changePkScript []byte) (*psbt.Packet, error) {
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("at least one staker utxo is required")
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+
+	var totalIn btcutil.Amount
+	for _, utxo := range utxos {
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: utxo.Txid, Index: utxo.Vout}, nil, nil))
+		totalIn += utxo.Amount
+	}
+
+	tx.AddTxOut(wire.NewTxOut(int64(stakingAmount), stakingPkScript))
+	tx.AddTxOut(wire.NewTxOut(0, opReturnPkScript))
+
+	change := totalIn - stakingAmount
+	if change < 0 {
+		return nil, fmt.Errorf("staker utxos total %d is below the staking amount %d", totalIn, stakingAmount)
+	}
+	if change > 0 {
+		tx.AddTxOut(wire.NewTxOut(int64(change), changePkScript))
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PSBT: %w", err)
+	}
+
+	for i, utxo := range utxos {
+		packet.Inputs[i].WitnessUtxo = wire.NewTxOut(int64(utxo.Amount), utxo.PkScript)
+		packet.Inputs[i].SighashType = txscript.SigHashDefault
+		if utxo.InternalKey != nil {
+			packet.Inputs[i].TaprootInternalKey = utxo.InternalKey
+		}
+	}
+
+	return packet, nil
+}
+
+// BuildUnbondingPSBT constructs a BIP-174 PSBT for the unbonding
+// transaction, which spends the staking output via its unbonding tapleaf
+// (spendInfo, from StakingInfo.UnbondingPathSpendInfo) and pays the
+// computed unbonding output. BIP-371 taproot fields are populated so a
+// wallet can produce the script-path signature.
+func BuildUnbondingPSBT(
+	stakingTxid chainhash.Hash,
+	stakingOutputIndex uint32,
+	stakingOutput *wire.TxOut,
+	unbondingOutput *wire.TxOut,
+	spendInfo *btcstaking.SpendInfo,
+) (*psbt.Packet, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: stakingTxid, Index: stakingOutputIndex}, nil, nil))
+	tx.AddTxOut(unbondingOutput)
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PSBT: %w", err)
+	}
+
+	controlBlockBytes, err := spendInfo.ControlBlock.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize control block: %w", err)
+	}
+
+	packet.Inputs[0].WitnessUtxo = stakingOutput
+	packet.Inputs[0].SighashType = txscript.SigHashDefault
+	packet.Inputs[0].TaprootLeafScript = []*psbt.TaprootTapLeafScript{
+		{
+			ControlBlock: controlBlockBytes,
+			Script:       spendInfo.RevealedLeaf.Script,
+			LeafVersion:  spendInfo.RevealedLeaf.LeafVersion,
+		},
+	}
+	packet.Inputs[0].TaprootMerkleRoot = spendInfo.ControlBlock.RootHash(spendInfo.RevealedLeaf.Script)
+
+	return packet, nil
+}
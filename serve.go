@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// computeRequest is the body accepted by POST /compute.
+type computeRequest struct {
+	StakerPk  string   `json:"staker_pk"`
+	FpPks     []string `json:"fp_pks"`
+	Amount    int64    `json:"amount"`
+	Time      int      `json:"time"`
+	Network   string   `json:"network"`
+	BtcHeight uint64   `json:"btc_height"`
+}
+
+// networkParams maps a network name, as accepted by both the CLI's
+// -network flag and /compute's "network" field, to the matching
+// chaincfg.Params.
+func networkParams(network string) (*chaincfg.Params, error) {
+	switch network {
+	case "", "mainnet":
+		return &chaincfg.MainNetParams, nil
+	case "testnet3", "testnet":
+		return &chaincfg.TestNet3Params, nil
+	case "signet":
+		return &chaincfg.SigNetParams, nil
+	case "regtest":
+		return &chaincfg.RegressionNetParams, nil
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+}
+
+// parsePubKeys parses a list of hex public keys, returning an error instead
+// of exiting the process so it can be used from an HTTP handler.
+func parsePubKeys(hexKeys []string) ([]*btcec.PublicKey, error) {
+	pubKeys := make([]*btcec.PublicKey, 0, len(hexKeys))
+	for i, hexKey := range hexKeys {
+		pubKey, err := parsePubKey(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys, nil
+}
+
+// heightOrLatest maps a 0 btc_height (not requested) to the sentinel that
+// GetParamsForHeight treats as "select the latest activated version".
+func heightOrLatest(btcHeight uint64) uint32 {
+	if btcHeight == 0 {
+		return ^uint32(0)
+	}
+	return uint32(btcHeight)
+}
+
+// computeHandler builds the StakingComputation for a single request,
+// fetching and selecting Babylon parameters against apiURL the same way the
+// CLI does.
+func computeHandler(apiURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req computeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		net, err := networkParams(req.Network)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stakerPubKey, err := parsePubKey(req.StakerPk)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid staker_pk: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if len(req.FpPks) == 0 {
+			http.Error(w, "fp_pks must contain at least one key", http.StatusBadRequest)
+			return
+		}
+		fpPubKeys, err := parsePubKeys(req.FpPks)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid fp_pks: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		babylonParams, err := fetchBabylonParams(apiURL)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to fetch Babylon parameters: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		latest, err := babylonParams.GetParamsForHeight(heightOrLatest(req.BtcHeight))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		covenantPubKeys, err := parsePubKeys(latest.CovenantPks)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid covenant_pks from API: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		finalTime := req.Time
+		if finalTime == 0 {
+			finalTime = int(latest.MinStakingTimeBlocks)
+		}
+
+		if req.Amount < latest.MinStakingValueSat {
+			http.Error(w, fmt.Sprintf("amount %d is below minimum %d", req.Amount, latest.MinStakingValueSat), http.StatusBadRequest)
+			return
+		}
+		if req.Amount > latest.MaxStakingValueSat {
+			http.Error(w, fmt.Sprintf("amount %d exceeds maximum %d", req.Amount, latest.MaxStakingValueSat), http.StatusBadRequest)
+			return
+		}
+		if uint32(finalTime) < latest.MinStakingTimeBlocks {
+			http.Error(w, fmt.Sprintf("time %d is below minimum %d", finalTime, latest.MinStakingTimeBlocks), http.StatusBadRequest)
+			return
+		}
+		if uint32(finalTime) > latest.MaxStakingTimeBlocks {
+			http.Error(w, fmt.Sprintf("time %d exceeds maximum %d", finalTime, latest.MaxStakingTimeBlocks), http.StatusBadRequest)
+			return
+		}
+
+		comp, err := buildStakingComputation(
+			stakerPubKey,
+			fpPubKeys,
+			covenantPubKeys,
+			latest.CovenantQuorum,
+			uint16(finalTime),
+			latest.UnbondingTimeBlocks,
+			req.Amount,
+			net,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(comp); err != nil {
+			log.Printf("failed to encode /compute response: %v", err)
+		}
+	}
+}
+
+// runServe starts the HTTP API: `babylon_txcheck serve -listen :8080`.
+func runServe(args []string) {
+	serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := serveFlags.String("listen", ":8080", "Address to listen on")
+	apiURL := serveFlags.String("api", "https://staking-api.babylonlabs.io/v2/network-info", "Babylon API endpoint")
+	serveFlags.Parse(args)
+
+	http.HandleFunc("/compute", computeHandler(*apiURL))
+
+	fmt.Printf("Listening on %s (POST /compute)\n", *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}